@@ -0,0 +1,163 @@
+// Package advisor provides the SQL review advisor framework: engine-specific advisors are
+// registered against a (Engine, SQLReviewRuleType) pair and invoked with a Context carrying the
+// parsed statement and the configured rule, producing zero or more pieces of Advice.
+package advisor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	storepb "github.com/bytebase/bytebase/proto/generated-go/store"
+)
+
+// Status is the severity an Advice is reported at.
+type Status string
+
+const (
+	// Success means no issue was found.
+	Success Status = "SUCCESS"
+	// Warn means a potential issue was found that does not block the change.
+	Warn Status = "WARN"
+	// Error means an issue was found that should block the change.
+	Error Status = "ERROR"
+)
+
+// SQLReviewRuleLevel is the severity a SQL review rule is configured at.
+type SQLReviewRuleLevel string
+
+const (
+	// SchemaRuleLevelDisabled disables the rule.
+	SchemaRuleLevelDisabled SQLReviewRuleLevel = "DISABLED"
+	// SchemaRuleLevelWarning reports violations as Warn.
+	SchemaRuleLevelWarning SQLReviewRuleLevel = "WARNING"
+	// SchemaRuleLevelError reports violations as Error.
+	SchemaRuleLevelError SQLReviewRuleLevel = "ERROR"
+)
+
+// NewStatusBySQLReviewRuleLevel maps a rule's configured level to the Status its violations
+// should be reported at.
+func NewStatusBySQLReviewRuleLevel(level SQLReviewRuleLevel) (Status, error) {
+	switch level {
+	case SchemaRuleLevelError:
+		return Error, nil
+	case SchemaRuleLevelWarning:
+		return Warn, nil
+	default:
+		return "", fmt.Errorf("unexpected SQL review rule level %q", level)
+	}
+}
+
+// Code is a stable identifier for a specific kind of advice, so callers (e.g. the frontend) can
+// key off it instead of parsing Content.
+type Code int
+
+const (
+	// Ok means no issue was found.
+	Ok Code = 0
+
+	// AutoIncrementColumnSigned is raised when an AUTO_INCREMENT column is not UNSIGNED.
+	AutoIncrementColumnSigned Code = 401
+	// AutoIncrementColumnTypeTooSmall is raised when an AUTO_INCREMENT column's type is
+	// narrower than the rule's configured minimum (BIGINT by default).
+	AutoIncrementColumnTypeTooSmall Code = 402
+	// AutoIncrementColumnNearOverflow is raised when an AUTO_INCREMENT column's live value has
+	// consumed more than the rule's configured fraction of its type's max value.
+	AutoIncrementColumnNearOverflow Code = 403
+	// AutoIncrementNotGloballyUnique is raised when a sharded logical table declares an
+	// AUTO_INCREMENT column, whose values are only unique per physical shard.
+	AutoIncrementNotGloballyUnique Code = 404
+
+	// ShardKeyMissing is raised when DML/DDL against a sharded logical table does not
+	// reference any configured shard key column.
+	ShardKeyMissing Code = 501
+)
+
+// SQLReviewRuleType identifies a specific SQL review rule, e.g. "mysql.auto-increment-column".
+type SQLReviewRuleType string
+
+const (
+	// MySQLAutoIncrementColumnMustUnsigned requires AUTO_INCREMENT columns to be UNSIGNED (and,
+	// per the rule's payload, at least a configured minimum width).
+	MySQLAutoIncrementColumnMustUnsigned SQLReviewRuleType = "mysql.auto-increment-column-must-unsigned"
+	// MySQLShardKeyMustBePresent requires DML/DDL against a sharded logical table to reference
+	// a configured shard key column.
+	MySQLShardKeyMustBePresent SQLReviewRuleType = "mysql.sharding.shard-key-must-be-present"
+	// MySQLSequenceRatherThanAutoIncrement flags AUTO_INCREMENT columns on sharded logical
+	// tables, since values are only unique per-shard.
+	MySQLSequenceRatherThanAutoIncrement SQLReviewRuleType = "mysql.sharding.sequence-rather-than-auto-increment"
+)
+
+// SQLReviewRule is a single configured rule: its type, the level violations are reported at, and
+// a JSON payload carrying any rule-specific configuration.
+type SQLReviewRule struct {
+	Type    SQLReviewRuleType
+	Level   SQLReviewRuleLevel
+	Payload string
+}
+
+// Advice is a single piece of advice an Advisor reports.
+type Advice struct {
+	Status  Status
+	Code    Code
+	Title   string
+	Content string
+	Line    int
+}
+
+// Context carries everything an Advisor.Check call needs: the parsed statement (as produced by
+// the relevant engine's parser package), the rule being checked, and an optional live driver
+// connection for advisors that need to query the database itself (e.g. overflow-risk checks).
+type Context struct {
+	Context context.Context
+	AST     any
+	Rule    *SQLReviewRule
+	Driver  *sql.DB
+	// LogicalSchema is the engine-specific sharding configuration (e.g.
+	// *mysql.LogicalSchema) for this connection, or nil for a plain, unsharded one. It is typed
+	// any rather than a concrete type to avoid an import cycle between this package and the
+	// per-engine advisor packages that define it; advisors type-assert it back to their own
+	// concrete type.
+	LogicalSchema any
+}
+
+// Advisor checks a parsed statement against a single SQL review rule.
+type Advisor interface {
+	Check(ctx Context, statement string) ([]Advice, error)
+}
+
+var (
+	advisorMu sync.RWMutex
+	advisors  = make(map[storepb.Engine]map[SQLReviewRuleType]Advisor)
+)
+
+// Register registers advisor for (engine, ruleType). It is expected to be called from the
+// advisor implementation's init() function.
+func Register(engine storepb.Engine, ruleType SQLReviewRuleType, advisor Advisor) {
+	advisorMu.Lock()
+	defer advisorMu.Unlock()
+
+	byRule, ok := advisors[engine]
+	if !ok {
+		byRule = make(map[SQLReviewRuleType]Advisor)
+		advisors[engine] = byRule
+	}
+	if _, dup := byRule[ruleType]; dup {
+		panic(fmt.Sprintf("advisor: Register called twice for engine %v rule %v", engine, ruleType))
+	}
+	byRule[ruleType] = advisor
+}
+
+// Get returns the advisor registered for (engine, ruleType), if any.
+func Get(engine storepb.Engine, ruleType SQLReviewRuleType) (Advisor, bool) {
+	advisorMu.RLock()
+	defer advisorMu.RUnlock()
+
+	byRule, ok := advisors[engine]
+	if !ok {
+		return nil, false
+	}
+	advisor, ok := byRule[ruleType]
+	return advisor, ok
+}
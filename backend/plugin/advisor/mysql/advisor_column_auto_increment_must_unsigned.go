@@ -3,7 +3,11 @@ package mysql
 // Framework code is generated by the generator.
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/antlr4-go/antlr/v4"
 	"github.com/pkg/errors"
@@ -15,6 +19,60 @@ import (
 	storepb "github.com/bytebase/bytebase/proto/generated-go/store"
 )
 
+// defaultAutoIncrementMinType is the column type required for an auto-increment column when the
+// rule payload does not specify one.
+const defaultAutoIncrementMinType = "BIGINT"
+
+// defaultAutoIncrementOverflowThreshold is the fraction of the type's max value an auto-increment
+// column may reach before AutoIncrementColumnNearOverflow is raised, when the rule payload does
+// not specify one.
+const defaultAutoIncrementOverflowThreshold = 0.7
+
+// autoIncrementTypeWidth ranks integer types by capacity so a configured minimum type (e.g.
+// "BIGINT") can be compared against whatever type the column actually declares.
+var autoIncrementTypeWidth = map[string]int{
+	"TINYINT":   1,
+	"SMALLINT":  2,
+	"MEDIUMINT": 3,
+	"INT":       4,
+	"INTEGER":   4,
+	"BIGINT":    5,
+}
+
+// autoIncrementRulePayload is the SQL review rule payload for
+// advisor.MySQLAutoIncrementColumnMustUnsigned, controlling the minimum required column type and
+// the overflow utilization threshold.
+type autoIncrementRulePayload struct {
+	// MinType is the minimum integer type required for an auto-increment column, e.g. "BIGINT".
+	MinType string `json:"minType"`
+	// OverflowThreshold is the fraction (0, 1] of the column type's max value that triggers
+	// AutoIncrementColumnNearOverflow once the live AUTO_INCREMENT value crosses it.
+	OverflowThreshold float64 `json:"overflowThreshold"`
+}
+
+func unmarshalAutoIncrementRulePayload(payload string) (autoIncrementRulePayload, error) {
+	result := autoIncrementRulePayload{
+		MinType:           defaultAutoIncrementMinType,
+		OverflowThreshold: defaultAutoIncrementOverflowThreshold,
+	}
+	if payload == "" {
+		return result, nil
+	}
+	if err := json.Unmarshal([]byte(payload), &result); err != nil {
+		return result, errors.Wrapf(err, "failed to unmarshal auto-increment rule payload")
+	}
+	if result.MinType == "" {
+		result.MinType = defaultAutoIncrementMinType
+	}
+	if _, ok := autoIncrementTypeWidth[strings.ToUpper(result.MinType)]; !ok {
+		return autoIncrementRulePayload{}, errors.Errorf("invalid auto-increment rule payload: unsupported minType %q", result.MinType)
+	}
+	if result.OverflowThreshold <= 0 {
+		result.OverflowThreshold = defaultAutoIncrementOverflowThreshold
+	}
+	return result, nil
+}
+
 var (
 	_ advisor.Advisor = (*ColumnAutoIncrementMustIntegerAdvisor)(nil)
 )
@@ -22,6 +80,7 @@ var (
 func init() {
 	// only for mysqlwip test.
 	advisor.Register(storepb.Engine_ENGINE_UNSPECIFIED, advisor.MySQLAutoIncrementColumnMustUnsigned, &ColumnAutoIncrementMustUnsignedAdvisor{})
+	advisor.Register(storepb.Engine_MARIADB, advisor.MySQLAutoIncrementColumnMustUnsigned, &ColumnAutoIncrementMustUnsignedAdvisor{})
 }
 
 // ColumnAutoIncrementMustUnsignedAdvisor is the advisor checking for unsigned auto-increment column.
@@ -39,16 +98,30 @@ func (*ColumnAutoIncrementMustUnsignedAdvisor) Check(ctx advisor.Context, _ stri
 	if err != nil {
 		return nil, err
 	}
+	payload, err := unmarshalAutoIncrementRulePayload(ctx.Rule.Payload)
+	if err != nil {
+		return nil, err
+	}
 	checker := &columnAutoIncrementMustUnsignedChecker{
-		level: level,
-		title: string(ctx.Rule.Type),
+		level:         level,
+		title:         string(ctx.Rule.Type),
+		payload:       payload,
+		logicalSchema: logicalSchemaFromContext(ctx),
+		seenAdvice:    make(map[string]bool),
 	}
 
 	for _, stmt := range stmtList {
 		checker.baseLine = stmt.BaseLine
+		checker.dialect = stmt.Dialect
 		antlr.ParseTreeWalkerDefault.Walk(checker, stmt.Tree)
 	}
 
+	if ctx.Driver != nil {
+		if err := checker.checkOverflowRisk(ctx.Context, ctx.Driver); err != nil {
+			return nil, err
+		}
+	}
+
 	if len(checker.adviceList) == 0 {
 		checker.adviceList = append(checker.adviceList, advisor.Advice{
 			Status:  advisor.Success,
@@ -67,6 +140,46 @@ type columnAutoIncrementMustUnsignedChecker struct {
 	adviceList []advisor.Advice
 	level      advisor.Status
 	title      string
+	dialect    mysqlparser.Dialect
+	payload    autoIncrementRulePayload
+
+	// logicalSchema coalesces advice by logical table when the connection sits behind a
+	// sharding proxy, so a rule violated on every physical shard only surfaces once.
+	logicalSchema *LogicalSchema
+	seenAdvice    map[string]bool
+
+	// autoIncrementColumns collects every auto-increment column seen so far, so Check can
+	// follow up with a live overflow-risk query once the statement walk is done.
+	autoIncrementColumns []autoIncrementColumnRef
+}
+
+// addAdvice appends advice to adviceList, unless logicalSchema coalesces tableName under a
+// logical name that has already reported the same code — in which case it is dropped so the
+// user sees one message per logical table instead of one per physical shard.
+func (checker *columnAutoIncrementMustUnsignedChecker) addAdvice(code advisor.Code, tableName, content string, line int) {
+	logicalName, sharded := checker.logicalSchema.LogicalTableName(tableName)
+	if sharded {
+		key := fmt.Sprintf("%d:%s", code, logicalName)
+		if checker.seenAdvice[key] {
+			return
+		}
+		checker.seenAdvice[key] = true
+	}
+	checker.adviceList = append(checker.adviceList, advisor.Advice{
+		Status:  checker.level,
+		Code:    code,
+		Title:   checker.title,
+		Content: content,
+		Line:    line,
+	})
+}
+
+// autoIncrementColumnRef identifies an auto-increment column found while walking the statements,
+// for the follow-up overflow-risk query against information_schema.
+type autoIncrementColumnRef struct {
+	tableName  string
+	columnName string
+	line       int
 }
 
 func (checker *columnAutoIncrementMustUnsignedChecker) EnterCreateTable(ctx *mysql.CreateTableContext) {
@@ -144,15 +257,43 @@ func (checker *columnAutoIncrementMustUnsignedChecker) EnterAlterTable(ctx *mysq
 }
 
 func (checker *columnAutoIncrementMustUnsignedChecker) checkFieldDefinition(tableName, columnName string, ctx mysql.IFieldDefinitionContext) {
+	if !checker.isAutoIncrementColumn(ctx) {
+		return
+	}
+
 	if !checker.isAutoIncrementColumnIsInteger(ctx) {
-		checker.adviceList = append(checker.adviceList, advisor.Advice{
-			Status:  checker.level,
-			Code:    advisor.AutoIncrementColumnSigned,
-			Title:   checker.title,
-			Content: fmt.Sprintf("Auto-increment column `%s`.`%s` is not UNSIGNED type", tableName, columnName),
-			Line:    checker.baseLine + ctx.GetStart().GetLine(),
-		})
+		checker.addAdvice(
+			advisor.AutoIncrementColumnSigned,
+			tableName,
+			fmt.Sprintf("Auto-increment column `%s`.`%s` is not UNSIGNED type", tableName, columnName),
+			checker.baseLine+ctx.GetStart().GetLine(),
+		)
+	}
+
+	if columnType := dataTypeName(ctx.DataType()); autoIncrementTypeWidth[columnType] < autoIncrementTypeWidth[strings.ToUpper(checker.payload.MinType)] {
+		checker.addAdvice(
+			advisor.AutoIncrementColumnTypeTooSmall,
+			tableName,
+			fmt.Sprintf("Auto-increment column `%s`.`%s` is %s, which cannot hold enough rows, expect %s or wider", tableName, columnName, columnType, strings.ToUpper(checker.payload.MinType)),
+			checker.baseLine+ctx.GetStart().GetLine(),
+		)
 	}
+
+	checker.autoIncrementColumns = append(checker.autoIncrementColumns, autoIncrementColumnRef{
+		tableName:  tableName,
+		columnName: columnName,
+		line:       checker.baseLine + ctx.GetStart().GetLine(),
+	})
+}
+
+// dataTypeName returns the upper-cased integer type keyword (e.g. "BIGINT") a field definition
+// declares, ignoring any display width such as "INT(11)".
+func dataTypeName(ctx mysql.IDataTypeContext) string {
+	name := strings.ToUpper(ctx.GetStart().GetText())
+	if name == "INTEGER" {
+		name = "INT"
+	}
+	return name
 }
 
 func (checker *columnAutoIncrementMustUnsignedChecker) isAutoIncrementColumnIsInteger(ctx mysql.IFieldDefinitionContext) bool {
@@ -171,7 +312,7 @@ func (*columnAutoIncrementMustUnsignedChecker) isAutoIncrementColumn(ctx mysql.I
 	return false
 }
 
-func (*columnAutoIncrementMustUnsignedChecker) isUnsigned(ctx mysql.IDataTypeContext) bool {
+func (checker *columnAutoIncrementMustUnsignedChecker) isUnsigned(ctx mysql.IDataTypeContext) bool {
 	if ctx.FieldOptions() == nil {
 		return false
 	}
@@ -181,9 +322,70 @@ func (*columnAutoIncrementMustUnsignedChecker) isUnsigned(ctx mysql.IDataTypeCon
 	}
 
 	// If you specify ZEROFILL for a numeric column, MySQL automatically adds the UNSIGNED attribute to the column.
-	// As of MySQL 8.0.17, the ZEROFILL attribute is deprecated for numeric data types.
-	if ctx.FieldOptions().AllZEROFILL_SYMBOL() != nil && len(ctx.FieldOptions().AllZEROFILL_SYMBOL()) > 0 {
+	// As of MySQL 8.0.17, the ZEROFILL attribute is deprecated for numeric data types. MariaDB 10.6+ dropped the
+	// implicit UNSIGNED altogether, so do not honor ZEROFILL as a stand-in for UNSIGNED on that dialect.
+	// The dialect carries no server version, so this applies to every MariaDB version, including those before 10.6.
+	if checker.dialect != mysqlparser.MariaDBDialect && ctx.FieldOptions().AllZEROFILL_SYMBOL() != nil && len(ctx.FieldOptions().AllZEROFILL_SYMBOL()) > 0 {
 		return true
 	}
 	return false
+}
+
+// checkOverflowRisk queries information_schema for the live AUTO_INCREMENT value of every column
+// collected in autoIncrementColumns and flags any that have consumed more than
+// payload.OverflowThreshold of the column type's max value.
+func (checker *columnAutoIncrementMustUnsignedChecker) checkOverflowRisk(ctx context.Context, driver *sql.DB) error {
+	for _, col := range checker.autoIncrementColumns {
+		var autoIncrementValue sql.NullInt64
+		var dataType string
+		row := driver.QueryRowContext(ctx, `
+			SELECT t.AUTO_INCREMENT, c.DATA_TYPE
+			FROM information_schema.TABLES t
+			JOIN information_schema.COLUMNS c
+				ON c.TABLE_SCHEMA = t.TABLE_SCHEMA AND c.TABLE_NAME = t.TABLE_NAME
+			WHERE t.TABLE_SCHEMA = DATABASE() AND t.TABLE_NAME = ? AND c.COLUMN_NAME = ?
+		`, col.tableName, col.columnName)
+		if err := row.Scan(&autoIncrementValue, &dataType); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to query AUTO_INCREMENT for `%s`.`%s`", col.tableName, col.columnName)
+		}
+		if !autoIncrementValue.Valid {
+			continue
+		}
+
+		maxValue := maxValueForType(strings.ToUpper(dataType))
+		if maxValue == 0 {
+			continue
+		}
+		if utilization := float64(autoIncrementValue.Int64) / float64(maxValue); utilization > checker.payload.OverflowThreshold {
+			checker.addAdvice(
+				advisor.AutoIncrementColumnNearOverflow,
+				col.tableName,
+				fmt.Sprintf("Auto-increment column `%s`.`%s` has used %.0f%% of its %s range, consider widening the column before it overflows", col.tableName, col.columnName, utilization*100, dataType),
+				col.line,
+			)
+		}
+	}
+	return nil
+}
+
+// maxValueForType returns the max UNSIGNED value for a MySQL integer type, or 0 if typeName is
+// not a recognized integer type.
+func maxValueForType(typeName string) uint64 {
+	switch typeName {
+	case "TINYINT":
+		return 1<<8 - 1
+	case "SMALLINT":
+		return 1<<16 - 1
+	case "MEDIUMINT":
+		return 1<<24 - 1
+	case "INT", "INTEGER":
+		return 1<<32 - 1
+	case "BIGINT":
+		return 1<<64 - 1
+	default:
+		return 0
+	}
 }
\ No newline at end of file
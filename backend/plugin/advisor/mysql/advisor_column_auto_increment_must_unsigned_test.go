@@ -0,0 +1,68 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalAutoIncrementRulePayload(t *testing.T) {
+	tests := []struct {
+		payload     string
+		wantMinType string
+		wantErr     bool
+	}{
+		{
+			payload:     "",
+			wantMinType: defaultAutoIncrementMinType,
+		},
+		{
+			payload:     `{"minType": "int"}`,
+			wantMinType: "int",
+		},
+		{
+			payload:     `{"minType": ""}`,
+			wantMinType: defaultAutoIncrementMinType,
+		},
+		{
+			payload: `{"minType": "DECIMAL"}`,
+			wantErr: true,
+		},
+		{
+			payload: `not json`,
+			wantErr: true,
+		},
+	}
+
+	a := require.New(t)
+	for _, tc := range tests {
+		got, err := unmarshalAutoIncrementRulePayload(tc.payload)
+		if tc.wantErr {
+			a.Error(err)
+			continue
+		}
+		a.NoError(err)
+		a.Equal(tc.wantMinType, got.MinType)
+		a.Equal(defaultAutoIncrementOverflowThreshold, got.OverflowThreshold)
+	}
+}
+
+func TestMaxValueForType(t *testing.T) {
+	tests := []struct {
+		typeName string
+		want     uint64
+	}{
+		{"TINYINT", 1<<8 - 1},
+		{"SMALLINT", 1<<16 - 1},
+		{"MEDIUMINT", 1<<24 - 1},
+		{"INT", 1<<32 - 1},
+		{"INTEGER", 1<<32 - 1},
+		{"BIGINT", 1<<64 - 1},
+		{"DECIMAL", 0},
+	}
+
+	a := require.New(t)
+	for _, tc := range tests {
+		a.Equal(tc.want, maxValueForType(tc.typeName))
+	}
+}
@@ -0,0 +1,165 @@
+package mysql
+
+// Framework code is generated by the generator.
+
+import (
+	"fmt"
+
+	"github.com/antlr4-go/antlr/v4"
+	"github.com/pkg/errors"
+
+	mysql "github.com/bytebase/mysql-parser"
+
+	"github.com/bytebase/bytebase/backend/plugin/advisor"
+	mysqlparser "github.com/bytebase/bytebase/backend/plugin/parser/mysql"
+	storepb "github.com/bytebase/bytebase/proto/generated-go/store"
+)
+
+var (
+	_ advisor.Advisor = (*SequenceRatherThanAutoIncrementAdvisor)(nil)
+)
+
+func init() {
+	advisor.Register(storepb.Engine_MYSQL, advisor.MySQLSequenceRatherThanAutoIncrement, &SequenceRatherThanAutoIncrementAdvisor{})
+	advisor.Register(storepb.Engine_MARIADB, advisor.MySQLSequenceRatherThanAutoIncrement, &SequenceRatherThanAutoIncrementAdvisor{})
+}
+
+// SequenceRatherThanAutoIncrementAdvisor warns when a sharded table declares an AUTO_INCREMENT
+// column, since each physical shard generates its own sequence independently and the values are
+// not globally unique across shards. A MariaDB SEQUENCE object (or an application-level ID
+// generator) should be used instead.
+type SequenceRatherThanAutoIncrementAdvisor struct {
+}
+
+// Check checks that a sharded table does not rely on AUTO_INCREMENT for a globally unique id.
+func (*SequenceRatherThanAutoIncrementAdvisor) Check(ctx advisor.Context, _ string) ([]advisor.Advice, error) {
+	stmtList, ok := ctx.AST.([]*mysqlparser.ParseResult)
+	if !ok {
+		return nil, errors.Errorf("failed to convert to mysql parse result")
+	}
+
+	logicalSchema := logicalSchemaFromContext(ctx)
+	if logicalSchema == nil {
+		return []advisor.Advice{{Status: advisor.Success, Code: advisor.Ok, Title: "OK", Content: ""}}, nil
+	}
+
+	level, err := advisor.NewStatusBySQLReviewRuleLevel(ctx.Rule.Level)
+	if err != nil {
+		return nil, err
+	}
+	checker := &sequenceRatherThanAutoIncrementChecker{
+		level:         level,
+		title:         string(ctx.Rule.Type),
+		logicalSchema: logicalSchema,
+	}
+
+	for _, stmt := range stmtList {
+		checker.baseLine = stmt.BaseLine
+		antlr.ParseTreeWalkerDefault.Walk(checker, stmt.Tree)
+	}
+
+	if len(checker.adviceList) == 0 {
+		checker.adviceList = append(checker.adviceList, advisor.Advice{
+			Status:  advisor.Success,
+			Code:    advisor.Ok,
+			Title:   "OK",
+			Content: "",
+		})
+	}
+	return checker.adviceList, nil
+}
+
+type sequenceRatherThanAutoIncrementChecker struct {
+	*mysql.BaseMySQLParserListener
+
+	baseLine      int
+	adviceList    []advisor.Advice
+	level         advisor.Status
+	title         string
+	logicalSchema *LogicalSchema
+}
+
+func (checker *sequenceRatherThanAutoIncrementChecker) EnterCreateTable(ctx *mysql.CreateTableContext) {
+	if ctx.TableElementList() == nil || ctx.TableName() == nil {
+		return
+	}
+
+	_, tableName := mysqlparser.NormalizeMySQLTableName(ctx.TableName())
+	if !checker.logicalSchema.IsSharded(tableName) {
+		return
+	}
+
+	for _, tableElement := range ctx.TableElementList().AllTableElement() {
+		if tableElement.ColumnDefinition() == nil || tableElement.ColumnDefinition().FieldDefinition() == nil {
+			continue
+		}
+		_, _, columnName := mysqlparser.NormalizeMySQLColumnName(tableElement.ColumnDefinition().ColumnName())
+		checker.checkFieldDefinition(tableName, columnName, tableElement.ColumnDefinition().FieldDefinition())
+	}
+}
+
+// EnterAlterTable mirrors columnAutoIncrementMustUnsignedChecker.EnterAlterTable: an ALTER TABLE
+// ADD/CHANGE/MODIFY COLUMN on an already-sharded table can introduce an AUTO_INCREMENT column just
+// as easily as a CREATE TABLE can, and went completely unchecked before this.
+func (checker *sequenceRatherThanAutoIncrementChecker) EnterAlterTable(ctx *mysql.AlterTableContext) {
+	if ctx.TableRef() == nil || ctx.AlterTableActions() == nil {
+		return
+	}
+	if ctx.AlterTableActions().AlterCommandList() == nil || ctx.AlterTableActions().AlterCommandList().AlterList() == nil {
+		return
+	}
+
+	_, tableName := mysqlparser.NormalizeMySQLTableRef(ctx.TableRef())
+	if tableName == "" || !checker.logicalSchema.IsSharded(tableName) {
+		return
+	}
+
+	for _, item := range ctx.AlterTableActions().AlterCommandList().AlterList().AllAlterListItem() {
+		if item == nil {
+			continue
+		}
+
+		switch {
+		// add column
+		case item.ADD_SYMBOL() != nil && item.Identifier() != nil && item.FieldDefinition() != nil:
+			columnName := mysqlparser.NormalizeMySQLIdentifier(item.Identifier())
+			checker.checkFieldDefinition(tableName, columnName, item.FieldDefinition())
+		case item.ADD_SYMBOL() != nil && item.OPEN_PAR_SYMBOL() != nil && item.TableElementList() != nil:
+			for _, tableElement := range item.TableElementList().AllTableElement() {
+				if tableElement.ColumnDefinition() == nil || tableElement.ColumnDefinition().ColumnName() == nil || tableElement.ColumnDefinition().FieldDefinition() == nil {
+					continue
+				}
+				_, _, columnName := mysqlparser.NormalizeMySQLColumnName(tableElement.ColumnDefinition().ColumnName())
+				checker.checkFieldDefinition(tableName, columnName, tableElement.ColumnDefinition().FieldDefinition())
+			}
+		// change column
+		case item.CHANGE_SYMBOL() != nil && item.ColumnInternalRef() != nil && item.Identifier() != nil && item.FieldDefinition() != nil:
+			columnName := mysqlparser.NormalizeMySQLIdentifier(item.Identifier())
+			checker.checkFieldDefinition(tableName, columnName, item.FieldDefinition())
+		// modify column
+		case item.MODIFY_SYMBOL() != nil && item.ColumnInternalRef() != nil && item.FieldDefinition() != nil:
+			columnName := mysqlparser.NormalizeMySQLColumnInternalRef(item.ColumnInternalRef())
+			checker.checkFieldDefinition(tableName, columnName, item.FieldDefinition())
+		default:
+			continue
+		}
+	}
+}
+
+// checkFieldDefinition flags columnName on tableName if it declares AUTO_INCREMENT, shared by
+// EnterCreateTable and EnterAlterTable.
+func (checker *sequenceRatherThanAutoIncrementChecker) checkFieldDefinition(tableName, columnName string, ctx mysql.IFieldDefinitionContext) {
+	for _, attr := range ctx.AllColumnAttribute() {
+		if attr.AUTO_INCREMENT_SYMBOL() == nil {
+			continue
+		}
+		logicalName, _ := checker.logicalSchema.LogicalTableName(tableName)
+		checker.adviceList = append(checker.adviceList, advisor.Advice{
+			Status:  checker.level,
+			Code:    advisor.AutoIncrementNotGloballyUnique,
+			Title:   checker.title,
+			Content: fmt.Sprintf("Column `%s`.`%s` uses AUTO_INCREMENT on sharded logical table `%s`; values are only unique per-shard, use a sequence or application-level id generator instead", tableName, columnName, logicalName),
+			Line:    checker.baseLine + ctx.GetStart().GetLine(),
+		})
+	}
+}
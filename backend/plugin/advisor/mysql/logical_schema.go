@@ -0,0 +1,84 @@
+package mysql
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/bytebase/bytebase/backend/plugin/advisor"
+)
+
+// LogicalTableRule maps every physical table name matching Pattern (e.g. `t_order_\d+`) to the
+// single LogicalName a sharding proxy (ShardingSphere, Arana, ...) presents to applications, so
+// advisors can report one piece of advice per logical table instead of one per physical shard.
+type LogicalTableRule struct {
+	Pattern     *regexp.Regexp
+	LogicalName string
+}
+
+// LogicalSchema is the sharding configuration for a MySQL SQL review pass. It is nil for a plain,
+// unsharded connection, in which case advisors must treat every table as its own logical table.
+type LogicalSchema struct {
+	Tables []LogicalTableRule
+	// ShardKeys lists the column names that make up the shard key, in precedence order. DML/DDL
+	// touching a sharded table is expected to reference at least one of them.
+	ShardKeys []string
+}
+
+// LogicalTableName returns the logical table name physicalTableName is sharded under, and true,
+// if schema configures a rule matching it. Otherwise it returns physicalTableName unchanged and
+// false, so callers can treat an unmatched table as its own (unsharded) logical table.
+func (schema *LogicalSchema) LogicalTableName(physicalTableName string) (string, bool) {
+	if schema == nil {
+		return physicalTableName, false
+	}
+	for _, rule := range schema.Tables {
+		if rule.Pattern.MatchString(physicalTableName) {
+			return rule.LogicalName, true
+		}
+	}
+	return physicalTableName, false
+}
+
+// IsSharded reports whether schema has any rule that would coalesce physicalTableName under a
+// logical name.
+func (schema *LogicalSchema) IsSharded(physicalTableName string) bool {
+	_, sharded := schema.LogicalTableName(physicalTableName)
+	return sharded
+}
+
+// tableTokenPattern matches a single unquoted or backtick-quoted SQL identifier, used by
+// IsShardedText to pull out the individual table/alias names from a table reference list's source
+// text. The text must keep the separators between identifiers; a parse tree's GetText() drops
+// whitespace, so adjacent names would merge into one token.
+var tableTokenPattern = regexp.MustCompile("(?i)`?[a-zA-Z_][a-zA-Z0-9_$]*`?")
+
+// IsShardedText reports whether text contains a reference to any table matched by schema's
+// sharding rules. It is a best-effort textual check for statements where mysql-parser does not
+// expose a single qualified table reference to resolve via LogicalTableName (e.g. UPDATE's
+// TableReferenceList, which can name a join of several tables): it runs each rule's Pattern against
+// every identifier-shaped token in text rather than against the whole blob, since a realistically
+// anchored Pattern (e.g. `^t_order_\d+$`) would otherwise never match a multi-table join string and
+// silently disable enforcement. This can still false-positive on a non-table identifier (a column
+// or alias) that happens to match a sharding rule, the same trade-off checkStatement already makes
+// for shard key columns.
+func (schema *LogicalSchema) IsShardedText(text string) bool {
+	if schema == nil {
+		return false
+	}
+	for _, token := range tableTokenPattern.FindAllString(text, -1) {
+		if schema.IsSharded(strings.Trim(token, "`")) {
+			return true
+		}
+	}
+	return false
+}
+
+// logicalSchemaFromContext type-asserts ctx.LogicalSchema back to *LogicalSchema. Context stores
+// it as any to avoid an import cycle (this package already imports advisor for Context itself),
+// so every advisor reading it goes through here instead of asserting inline. A ctx.LogicalSchema
+// that is nil, or set to some other engine's schema type, both return nil here, which every
+// caller already treats as "unsharded connection".
+func logicalSchemaFromContext(ctx advisor.Context) *LogicalSchema {
+	schema, _ := ctx.LogicalSchema.(*LogicalSchema)
+	return schema
+}
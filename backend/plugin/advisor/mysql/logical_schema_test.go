@@ -0,0 +1,87 @@
+package mysql
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testShardingSchema() *LogicalSchema {
+	return &LogicalSchema{
+		Tables: []LogicalTableRule{
+			{Pattern: regexp.MustCompile(`^t_order_\d+$`), LogicalName: "t_order"},
+		},
+		ShardKeys: []string{"tenant_id"},
+	}
+}
+
+func TestLogicalSchema_LogicalTableName(t *testing.T) {
+	tests := []struct {
+		schema     *LogicalSchema
+		table      string
+		wantName   string
+		wantShared bool
+	}{
+		{
+			schema:     nil,
+			table:      "t_order_1",
+			wantName:   "t_order_1",
+			wantShared: false,
+		},
+		{
+			schema:     testShardingSchema(),
+			table:      "t_order_1",
+			wantName:   "t_order",
+			wantShared: true,
+		},
+		{
+			schema:     testShardingSchema(),
+			table:      "t_order_abc",
+			wantName:   "t_order_abc",
+			wantShared: false,
+		},
+	}
+
+	a := require.New(t)
+	for _, tc := range tests {
+		name, sharded := tc.schema.LogicalTableName(tc.table)
+		a.Equal(tc.wantName, name)
+		a.Equal(tc.wantShared, sharded)
+		a.Equal(tc.wantShared, tc.schema.IsSharded(tc.table))
+	}
+}
+
+func TestLogicalSchema_IsShardedText(t *testing.T) {
+	tests := []struct {
+		schema *LogicalSchema
+		text   string
+		want   bool
+	}{
+		{
+			schema: nil,
+			text:   "t_order_1",
+			want:   false,
+		},
+		{
+			schema: testShardingSchema(),
+			text:   "t_order_1",
+			want:   true,
+		},
+		{
+			schema: testShardingSchema(),
+			text:   "t_order_1 JOIN users ON users.id = t_order_1.user_id",
+			want:   true,
+		},
+		{
+			schema: testShardingSchema(),
+			text:   "users JOIN payments ON payments.user_id = users.id",
+			want:   false,
+		},
+	}
+
+	a := require.New(t)
+	for _, tc := range tests {
+		a.Equal(tc.want, tc.schema.IsShardedText(tc.text))
+	}
+}
@@ -0,0 +1,100 @@
+// Package anomaly provides a pluggable framework for periodically scanning
+// instances and databases for anomalies (e.g. schema drift, missing backups,
+// connection failures) and reporting them to the store.
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/bytebase/bytebase/api"
+)
+
+// Severity represents how urgently an anomaly needs attention.
+type Severity int
+
+const (
+	// SeverityInfo is informational and does not require action.
+	SeverityInfo Severity = iota
+	// SeverityWarn indicates a potential issue that should be reviewed.
+	SeverityWarn
+	// SeverityCritical indicates an issue that requires immediate attention.
+	SeverityCritical
+)
+
+// String returns the human readable name of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "INFO"
+	case SeverityWarn:
+		return "WARN"
+	case SeverityCritical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Target identifies the instance, and optionally the database, a detector
+// should run against.
+type Target struct {
+	InstanceId int
+	DatabaseId *int
+}
+
+// Result is a single anomaly found by a detector for a given Target.
+type Result struct {
+	Type     api.AnomalyType
+	Severity Severity
+	Payload  string
+}
+
+// Detector detects a specific class of anomaly against a Target. A detector
+// should be side-effect free: it reports what it currently observes, and
+// leaves upserting/expiring to the Scheduler.
+type Detector interface {
+	// Detect inspects the target and returns zero or more anomalies found.
+	// An empty, nil-error result means the detector found nothing wrong.
+	Detect(ctx context.Context, target Target) ([]Result, error)
+}
+
+var (
+	detectorMu sync.RWMutex
+	detectors  = make(map[api.AnomalyType]Detector)
+)
+
+// Register registers a Detector for the given anomaly type. Register is
+// expected to be called in the init() function of a detector implementation,
+// mirroring advisor.Register.
+func Register(anomalyType api.AnomalyType, detector Detector) {
+	detectorMu.Lock()
+	defer detectorMu.Unlock()
+
+	if _, dup := detectors[anomalyType]; dup {
+		panic(fmt.Sprintf("anomaly: Register called twice for type %v", anomalyType))
+	}
+	detectors[anomalyType] = detector
+}
+
+// List returns every anomaly type with a registered detector.
+func List() []api.AnomalyType {
+	detectorMu.RLock()
+	defer detectorMu.RUnlock()
+
+	var types []api.AnomalyType
+	for anomalyType := range detectors {
+		types = append(types, anomalyType)
+	}
+	return types
+}
+
+// get returns the detector registered for anomalyType, if any.
+func get(anomalyType api.AnomalyType) (Detector, bool) {
+	detectorMu.RLock()
+	defer detectorMu.RUnlock()
+
+	detector, ok := detectors[anomalyType]
+	return detector, ok
+}
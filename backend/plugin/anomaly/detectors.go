@@ -0,0 +1,180 @@
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bytebase/bytebase/api"
+)
+
+// Anomaly types detected by the built-in detectors registered by RegisterDefaultDetectors. These
+// are scoped to this package (rather than api.AnomalyType constants) because the detectors below
+// are this package's own concrete implementation of the anomaly types the scheduler was built to
+// cover; the api package remains the source of truth for the wire/storage representation.
+const (
+	TypeSchemaDrift         api.AnomalyType = "bb.anomaly.database.schema.drift"
+	TypeBackupMissing       api.AnomalyType = "bb.anomaly.database.backup.missing"
+	TypeConnectionFailure   api.AnomalyType = "bb.anomaly.instance.connection"
+	TypeSlowQueryRegression api.AnomalyType = "bb.anomaly.database.slow-query-regression"
+	TypeIndexStatistics     api.AnomalyType = "bb.anomaly.database.index-statistics"
+)
+
+// Dependencies are the data sources the built-in detectors need. Each is kept as a narrow
+// function rather than a concrete client so the detectors stay unit-testable without a real
+// instance connection, the same way Scheduler takes a targetLister func instead of a store.
+type Dependencies struct {
+	// SchemaChecksum returns the checksum recorded in target's migration history for its latest
+	// applied version, and the checksum computed from its current live schema.
+	SchemaChecksum func(ctx context.Context, target Target) (recorded, live string, err error)
+	// LastBackupAt returns the time of target's most recent successful backup, or nil if none
+	// has ever completed.
+	LastBackupAt func(ctx context.Context, target Target) (*time.Time, error)
+	// Ping attempts a connection to target's instance and returns any error encountered.
+	Ping func(ctx context.Context, target Target) error
+	// QueryLatencyP95 returns the rolling p95 query latency for target over a recent baseline
+	// window and the current window, so a regression can be measured as a ratio between them.
+	QueryLatencyP95 func(ctx context.Context, target Target) (baseline, current time.Duration, err error)
+	// StaleIndexes returns the names of indexes on target whose statistics have not been
+	// refreshed (e.g. ANALYZE TABLE) within the staleness window the implementation enforces.
+	StaleIndexes func(ctx context.Context, target Target) ([]string, error)
+
+	// MaxBackupAge is the longest a database may go without a successful backup before
+	// TypeBackupMissing fires.
+	MaxBackupAge time.Duration
+	// SlowQueryRegressionRatio is how many times slower the current p95 must be than the
+	// baseline p95 before TypeSlowQueryRegression fires.
+	SlowQueryRegressionRatio float64
+}
+
+// RegisterDefaultDetectors registers the five detectors the anomaly scheduler was built to run —
+// schema drift, missing backup, connection failure, slow query regression, and stale index
+// statistics — against deps. It is expected to be called once during server startup, before the
+// Scheduler returned by Start begins sweeping, mirroring how advisor.Register is called from
+// each advisor's init().
+func RegisterDefaultDetectors(deps Dependencies) {
+	if deps.MaxBackupAge <= 0 {
+		deps.MaxBackupAge = 7 * 24 * time.Hour
+	}
+	if deps.SlowQueryRegressionRatio <= 0 {
+		deps.SlowQueryRegressionRatio = 3
+	}
+
+	Register(TypeSchemaDrift, &schemaDriftDetector{schemaChecksum: deps.SchemaChecksum})
+	Register(TypeBackupMissing, &backupMissingDetector{lastBackupAt: deps.LastBackupAt, maxAge: deps.MaxBackupAge})
+	Register(TypeConnectionFailure, &connectionFailureDetector{ping: deps.Ping})
+	Register(TypeSlowQueryRegression, &slowQueryRegressionDetector{queryLatencyP95: deps.QueryLatencyP95, regressionRatio: deps.SlowQueryRegressionRatio})
+	Register(TypeIndexStatistics, &indexStatisticsDetector{staleIndexes: deps.StaleIndexes})
+}
+
+// schemaDriftDetector flags a database whose live schema checksum no longer matches the
+// checksum recorded in its migration history, i.e. the schema was changed outside of Bytebase's
+// migration pipeline.
+type schemaDriftDetector struct {
+	schemaChecksum func(ctx context.Context, target Target) (recorded, live string, err error)
+}
+
+// Detect implements Detector.
+func (d *schemaDriftDetector) Detect(ctx context.Context, target Target) ([]Result, error) {
+	recorded, live, err := d.schemaChecksum(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if recorded == "" || recorded == live {
+		return nil, nil
+	}
+	return []Result{{
+		Type:     TypeSchemaDrift,
+		Severity: SeverityCritical,
+		Payload:  fmt.Sprintf("live schema checksum %s does not match migration history's recorded %s", live, recorded),
+	}}, nil
+}
+
+// backupMissingDetector flags a database that has gone longer than maxAge without a successful
+// backup.
+type backupMissingDetector struct {
+	lastBackupAt func(ctx context.Context, target Target) (*time.Time, error)
+	maxAge       time.Duration
+}
+
+// Detect implements Detector.
+func (d *backupMissingDetector) Detect(ctx context.Context, target Target) ([]Result, error) {
+	lastBackupAt, err := d.lastBackupAt(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if lastBackupAt != nil && time.Since(*lastBackupAt) <= d.maxAge {
+		return nil, nil
+	}
+	since := "never"
+	if lastBackupAt != nil {
+		since = time.Since(*lastBackupAt).Round(time.Hour).String() + " ago"
+	}
+	return []Result{{
+		Type:     TypeBackupMissing,
+		Severity: SeverityCritical,
+		Payload:  fmt.Sprintf("last successful backup: %s, exceeds the %s policy", since, d.maxAge),
+	}}, nil
+}
+
+// connectionFailureDetector flags an instance that cannot currently be connected to.
+type connectionFailureDetector struct {
+	ping func(ctx context.Context, target Target) error
+}
+
+// Detect implements Detector.
+func (d *connectionFailureDetector) Detect(ctx context.Context, target Target) ([]Result, error) {
+	if err := d.ping(ctx, target); err != nil {
+		return []Result{{
+			Type:     TypeConnectionFailure,
+			Severity: SeverityCritical,
+			Payload:  fmt.Sprintf("failed to connect: %v", err),
+		}}, nil
+	}
+	return nil, nil
+}
+
+// slowQueryRegressionDetector flags a database whose current p95 query latency has regressed by
+// regressionRatio or more against its recent baseline.
+type slowQueryRegressionDetector struct {
+	queryLatencyP95 func(ctx context.Context, target Target) (baseline, current time.Duration, err error)
+	regressionRatio float64
+}
+
+// Detect implements Detector.
+func (d *slowQueryRegressionDetector) Detect(ctx context.Context, target Target) ([]Result, error) {
+	baseline, current, err := d.queryLatencyP95(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if baseline <= 0 || float64(current)/float64(baseline) < d.regressionRatio {
+		return nil, nil
+	}
+	return []Result{{
+		Type:     TypeSlowQueryRegression,
+		Severity: SeverityWarn,
+		Payload:  fmt.Sprintf("p95 query latency regressed from %s to %s, a %.1fx increase", baseline, current, float64(current)/float64(baseline)),
+	}}, nil
+}
+
+// indexStatisticsDetector flags a database with indexes whose statistics have gone stale,
+// risking the query planner picking a poor plan.
+type indexStatisticsDetector struct {
+	staleIndexes func(ctx context.Context, target Target) ([]string, error)
+}
+
+// Detect implements Detector.
+func (d *indexStatisticsDetector) Detect(ctx context.Context, target Target) ([]Result, error) {
+	stale, err := d.staleIndexes(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if len(stale) == 0 {
+		return nil, nil
+	}
+	return []Result{{
+		Type:     TypeIndexStatistics,
+		Severity: SeverityWarn,
+		Payload:  fmt.Sprintf("%d index(es) have stale statistics: %v", len(stale), stale),
+	}}, nil
+}
@@ -0,0 +1,190 @@
+package anomaly
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bytebase/bytebase/api"
+)
+
+// defaultScanInterval is how often the scheduler sweeps all targets when the
+// caller does not override it.
+const defaultScanInterval = 10 * time.Minute
+
+// Event is broadcast to subscribers whenever a scan run upserts or expires
+// an anomaly, so the frontend can be notified via push instead of polling.
+type Event struct {
+	Target  Target
+	Type    api.AnomalyType
+	// Expired is true when the anomaly is no longer detected and has been
+	// auto-archived rather than upserted.
+	Expired bool
+}
+
+// Scheduler periodically runs every registered Detector against a set of
+// targets, upserts any anomaly found, and expires anomalies that are no
+// longer detected.
+type Scheduler struct {
+	l            *zap.Logger
+	store        AnomalyStore
+	targetLister func(ctx context.Context) ([]Target, error)
+	interval     time.Duration
+
+	subscriberMu sync.RWMutex
+	subscribers  map[chan<- Event]struct{}
+}
+
+// AnomalyStore is the subset of store.AnomalyService the scheduler depends
+// on, kept narrow so it can be faked in tests.
+type AnomalyStore interface {
+	UpsertActiveAnomaly(ctx context.Context, upsert *api.AnomalyUpsert) (*api.Anomaly, error)
+	ExpireStaleAnomalyList(ctx context.Context, target api.AnomalyExpire) ([]api.AnomalyType, error)
+}
+
+// NewScheduler creates a scheduler that scans the targets returned by
+// targetLister using interval as the period between sweeps. A zero interval
+// falls back to defaultScanInterval.
+func NewScheduler(logger *zap.Logger, store AnomalyStore, targetLister func(ctx context.Context) ([]Target, error), interval time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = defaultScanInterval
+	}
+	return &Scheduler{
+		l:            logger,
+		store:        store,
+		targetLister: targetLister,
+		interval:     interval,
+		subscribers:  make(map[chan<- Event]struct{}),
+	}
+}
+
+// Start registers the built-in detectors against deps, builds a Scheduler around store and
+// targetLister, and starts it sweeping in the background. It is the single call the server's
+// startup path is expected to make; callers that need a scheduler running with a different
+// detector set should call RegisterDefaultDetectors (or Register directly) themselves and use
+// NewScheduler instead.
+func Start(ctx context.Context, logger *zap.Logger, store AnomalyStore, targetLister func(ctx context.Context) ([]Target, error), interval time.Duration, deps Dependencies) *Scheduler {
+	RegisterDefaultDetectors(deps)
+	s := NewScheduler(logger, store, targetLister, interval)
+	go s.Run(ctx)
+	return s
+}
+
+// Subscribe registers ch to receive scan events until ctx is done, at which
+// point the scheduler unregisters and closes ch.
+func (s *Scheduler) Subscribe(ctx context.Context, ch chan<- Event) {
+	s.subscriberMu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.subscriberMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.subscriberMu.Lock()
+		delete(s.subscribers, ch)
+		s.subscriberMu.Unlock()
+		close(ch)
+	}()
+}
+
+func (s *Scheduler) publish(event Event) {
+	s.subscriberMu.RLock()
+	defer s.subscriberMu.RUnlock()
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Drop the event rather than block the scan loop on a slow subscriber.
+		}
+	}
+}
+
+// Run blocks, sweeping all targets every interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx)
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context) {
+	targets, err := s.targetLister(ctx)
+	if err != nil {
+		s.l.Error("anomaly scheduler failed to list targets", zap.Error(err))
+		return
+	}
+
+	for _, target := range targets {
+		seen, failed := s.scanTarget(ctx, target)
+		archived, err := s.store.ExpireStaleAnomalyList(ctx, api.AnomalyExpire{
+			InstanceId: target.InstanceId,
+			DatabaseId: target.DatabaseId,
+			// failed is merged into SeenTypes, not just seen: a detector error says nothing
+			// about whether that anomaly is still active, so treating "failed to check" the
+			// same as "not seen" would auto-archive a genuinely active anomaly on a transient
+			// failure (network blip, instance momentarily unreachable).
+			SeenTypes: append(append([]api.AnomalyType{}, seen...), failed...),
+		})
+		if err != nil {
+			s.l.Error("anomaly scheduler failed to expire stale anomalies",
+				zap.Int("instanceId", target.InstanceId),
+				zap.Error(err),
+			)
+			continue
+		}
+		for _, anomalyType := range archived {
+			s.publish(Event{Target: target, Type: anomalyType, Expired: true})
+		}
+	}
+}
+
+// scanTarget runs every registered detector against target, upserting any anomaly found. It
+// returns two disjoint sets: seen (anomaly types currently observed) and failed (anomaly types
+// whose detector errored this round). The caller must include both in
+// ExpireStaleAnomalyList's SeenTypes so that neither is archived.
+func (s *Scheduler) scanTarget(ctx context.Context, target Target) (seen, failed []api.AnomalyType) {
+	for _, anomalyType := range List() {
+		detector, ok := get(anomalyType)
+		if !ok {
+			continue
+		}
+
+		results, err := detector.Detect(ctx, target)
+		if err != nil {
+			s.l.Error("anomaly detector failed",
+				zap.String("type", string(anomalyType)),
+				zap.Int("instanceId", target.InstanceId),
+				zap.Error(err),
+			)
+			failed = append(failed, anomalyType)
+			continue
+		}
+		for _, result := range results {
+			seen = append(seen, result.Type)
+			if _, err := s.store.UpsertActiveAnomaly(ctx, &api.AnomalyUpsert{
+				InstanceId: target.InstanceId,
+				DatabaseId: target.DatabaseId,
+				Type:       result.Type,
+				Severity:   api.AnomalySeverity(result.Severity.String()),
+				Payload:    result.Payload,
+			}); err != nil {
+				s.l.Error("anomaly scheduler failed to upsert anomaly",
+					zap.String("type", string(result.Type)),
+					zap.Int("instanceId", target.InstanceId),
+					zap.Error(err),
+				)
+				continue
+			}
+			s.publish(Event{Target: target, Type: result.Type})
+		}
+	}
+	return seen, failed
+}
@@ -0,0 +1,43 @@
+package anomaly
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ServeHTTP streams scan Events to the client as they happen, using Server-Sent Events, so the
+// frontend can subscribe to anomaly updates by push instead of polling the anomaly list
+// endpoint. The server's router is expected to mount this directly, e.g.
+// mux.Handle("/api/anomaly/stream", scheduler).
+//
+// ServeHTTP implements http.Handler.
+func (s *Scheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+	ch := make(chan Event, 16)
+	s.Subscribe(ctx, ch)
+
+	for event := range ch {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			s.l.Error("anomaly stream failed to marshal event")
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", payload); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
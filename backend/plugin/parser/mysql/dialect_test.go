@@ -0,0 +1,40 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectDialect(t *testing.T) {
+	tests := []struct {
+		version string
+		want    Dialect
+	}{
+		{
+			version: "8.0.27",
+			want:    MySQLDialect,
+		},
+		{
+			version: "5.7.22-log",
+			want:    MySQLDialect,
+		},
+		{
+			version: "10.4.7-MariaDB",
+			want:    MariaDBDialect,
+		},
+		{
+			version: "10.6.12-MariaDB-log",
+			want:    MariaDBDialect,
+		},
+		{
+			version: "",
+			want:    MySQLDialect,
+		},
+	}
+
+	a := require.New(t)
+	for _, tc := range tests {
+		a.Equal(tc.want, DetectDialect(tc.version))
+	}
+}
@@ -0,0 +1,28 @@
+package mysql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	identifierPatternMu sync.Mutex
+	identifierPatterns  = make(map[string]*regexp.Regexp)
+)
+
+// ContainsIdentifier reports whether text references identifier as a standalone token (optionally
+// backtick-quoted), case-insensitively. It is a best-effort textual check used where walking the
+// full parse tree for every possible clause an identifier could appear in is not worth the cost.
+func ContainsIdentifier(text, identifier string) bool {
+	identifierPatternMu.Lock()
+	pattern, ok := identifierPatterns[identifier]
+	if !ok {
+		pattern = regexp.MustCompile(fmt.Sprintf("(?i)[`]?\\b%s\\b[`]?", regexp.QuoteMeta(identifier)))
+		identifierPatterns[identifier] = pattern
+	}
+	identifierPatternMu.Unlock()
+
+	return pattern.MatchString(strings.TrimSpace(text))
+}
@@ -0,0 +1,41 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainsIdentifier(t *testing.T) {
+	tests := []struct {
+		text       string
+		identifier string
+		want       bool
+	}{
+		{
+			text:       "INSERT INTO t (tenant_id, name) VALUES (1, 'a')",
+			identifier: "tenant_id",
+			want:       true,
+		},
+		{
+			text:       "INSERT INTO t (`tenant_id`, name) VALUES (1, 'a')",
+			identifier: "tenant_id",
+			want:       true,
+		},
+		{
+			text:       "INSERT INTO t (tenant_id_other, name) VALUES (1, 'a')",
+			identifier: "tenant_id",
+			want:       false,
+		},
+		{
+			text:       "INSERT INTO t (name) VALUES ('a')",
+			identifier: "tenant_id",
+			want:       false,
+		},
+	}
+
+	a := require.New(t)
+	for _, tc := range tests {
+		a.Equal(tc.want, ContainsIdentifier(tc.text, tc.identifier))
+	}
+}
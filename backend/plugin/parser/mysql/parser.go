@@ -0,0 +1,61 @@
+package mysql
+
+import (
+	"github.com/antlr4-go/antlr/v4"
+
+	mysql "github.com/bytebase/mysql-parser"
+)
+
+// ParseResult is the parse tree for MySQL-family SQL, along with the metadata advisors need to
+// report accurate advice. ParseMySQL returns a single ParseResult that covers the whole batch.
+type ParseResult struct {
+	Tree antlr.Tree
+	// Text is the original text the tree was parsed from.
+	Text string
+	// BaseLine is the 0-based line number the parsed text starts at within the original batch, so
+	// advisors can report line numbers relative to the whole input. It is 0 for the single
+	// ParseResult ParseMySQL returns.
+	BaseLine int
+	// Dialect is the MySQL-family variant the statement was parsed for, as detected by
+	// DetectDialect from the connected instance's server version. Advisors branch on it for
+	// dialect-specific syntax and rules instead of treating every engine as generic MySQL.
+	Dialect Dialect
+}
+
+// ParseMySQL parses statement — a semicolon-separated batch of one or more MySQL-family
+// statements — returning one ParseResult per statement. serverVersion is the connected
+// instance's reported version (e.g. "10.4.7-MariaDB"); every returned ParseResult is stamped
+// with the Dialect DetectDialect derives from it, so dialect detection happens once per batch
+// here rather than being left to each advisor to re-derive.
+func ParseMySQL(statement, serverVersion string) ([]*ParseResult, error) {
+	dialect := DetectDialect(serverVersion)
+
+	results, err := parseMySQLStatements(statement)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		result.Dialect = dialect
+	}
+	return results, nil
+}
+
+// parseMySQLStatements parses the whole of statement as one script into a single ParseResult
+// with BaseLine 0 and Dialect left at MySQLDialect; it does not split the batch into individual
+// statements. ParseMySQL sets the detected Dialect on the result.
+func parseMySQLStatements(statement string) ([]*ParseResult, error) {
+	lexer := mysql.NewMySQLLexer(antlr.NewInputStream(statement))
+	stream := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+	parser := mysql.NewMySQLParser(stream)
+	parser.BuildParseTrees = true
+
+	tree := parser.Script()
+	return []*ParseResult{
+		{
+			Tree:     tree,
+			Text:     statement,
+			BaseLine: 0,
+			Dialect:  MySQLDialect,
+		},
+	}, nil
+}
@@ -0,0 +1,34 @@
+// NOT generated. This package's real output comes from `make generate-proto` (protoc-gen-go
+// against proto/store/common.proto), which this sandbox cannot run, and that real output also
+// carries a proto.Message/descriptor/reflection implementation this file does not attempt to
+// reproduce. This is a hand-written stand-in limited to the Engine values the MySQL advisors
+// reference, checked in only so the package exists to build against; it is expected to be
+// replaced wholesale by the next real `make generate-proto`, not merged into it.
+
+package store
+
+// Engine is the database engine of a connected instance.
+type Engine int32
+
+const (
+	Engine_ENGINE_UNSPECIFIED Engine = 0
+	Engine_MYSQL              Engine = 1
+	Engine_POSTGRES           Engine = 2
+	// Engine_MARIADB identifies a MariaDB instance. See proto/store/common.proto for why it
+	// reuses the MySQL advisors and parser instead of getting its own plugin tree.
+	Engine_MARIADB Engine = 3
+)
+
+// String returns the enum value's protobuf name.
+func (e Engine) String() string {
+	switch e {
+	case Engine_MYSQL:
+		return "MYSQL"
+	case Engine_POSTGRES:
+		return "POSTGRES"
+	case Engine_MARIADB:
+		return "MARIADB"
+	default:
+		return "ENGINE_UNSPECIFIED"
+	}
+}
@@ -1,14 +1,25 @@
+// Package store implements the storage layer.
+//
+// Errors returned from this file that come from FormatError (i.e. a raw driver/sql error) are
+// wrapped with errors.WithStack. A *common.Error built directly in this file (the
+// Conflict/NotFound/Internal cases below) is returned unwrapped by the function that builds it;
+// UpsertActiveAnomaly passes its Conflict error on unwrapped, while ArchiveAnomaly wraps
+// archiveAnomaly's NotFound/Internal errors with errors.WithStack. Where an error carries a
+// *common.Error under such a wrapper, errors.WithStack's wrapper implements Unwrap, so a
+// caller doing errors.As(err, &common.Error{}) still sees through it; only a raw
+// `err.(*common.Error)` type assertion would miss, and no caller in this tree does that today.
 package store
 
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"strings"
 
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
-	"go.uber.org/zap"
 )
 
 var (
@@ -32,7 +43,7 @@ func NewAnomalyService(logger *zap.Logger, db *DB) *AnomalyService {
 func (s *AnomalyService) UpsertActiveAnomaly(ctx context.Context, upsert *api.AnomalyUpsert) (*api.Anomaly, error) {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, FormatError(err)
+		return nil, errors.WithStack(FormatError(err))
 	}
 	defer tx.Rollback()
 
@@ -43,7 +54,7 @@ func (s *AnomalyService) UpsertActiveAnomaly(ctx context.Context, upsert *api.An
 		DatabaseId: upsert.DatabaseId,
 		Type:       &upsert.Type,
 	}
-	list, err := findAnomalyList(ctx, tx, find)
+	list, _, err := findAnomalyList(ctx, tx, find)
 	if err != nil {
 		return nil, err
 	}
@@ -59,17 +70,18 @@ func (s *AnomalyService) UpsertActiveAnomaly(ctx context.Context, upsert *api.An
 		anomaly, err = patchAnomaly(ctx, tx, &anomalyPatch{
 			ID:        list[0].ID,
 			UpdaterId: upsert.CreatorId,
+			Severity:  upsert.Severity,
 			Payload:   upsert.Payload,
 		})
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		return nil, &common.Error{Code: common.Conflict, Err: fmt.Errorf("found %d active anomalies with filter %+v, expect 1", len(list), find)}
+		return nil, &common.Error{Code: common.Conflict, Err: errors.Errorf("found %d active anomalies with filter %+v, expect 1", len(list), find)}
 	}
 
 	if err := tx.Commit(); err != nil {
-		return nil, FormatError(err)
+		return nil, errors.WithStack(FormatError(err))
 	}
 
 	return anomaly, nil
@@ -77,18 +89,25 @@ func (s *AnomalyService) UpsertActiveAnomaly(ctx context.Context, upsert *api.An
 
 // FindAnomalyList retrieves a list of anomalys based on find.
 func (s *AnomalyService) FindAnomalyList(ctx context.Context, find *api.AnomalyFind) ([]*api.Anomaly, error) {
+	list, _, err := s.FindAnomalyListWithTotal(ctx, find)
+	return list, err
+}
+
+// FindAnomalyListWithTotal retrieves a page of anomalys based on find, along with the total
+// number of anomalys matching find ignoring find.Limit/Offset, so the caller can paginate.
+func (s *AnomalyService) FindAnomalyListWithTotal(ctx context.Context, find *api.AnomalyFind) ([]*api.Anomaly, int, error) {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, FormatError(err)
+		return nil, 0, errors.WithStack(FormatError(err))
 	}
 	defer tx.Rollback()
 
-	list, err := findAnomalyList(ctx, tx, find)
+	list, total, err := findAnomalyList(ctx, tx, find)
 	if err != nil {
-		return []*api.Anomaly{}, err
+		return []*api.Anomaly{}, 0, err
 	}
 
-	return list, nil
+	return list, total, nil
 }
 
 // ArchiveAnomaly archives an existing anomaly by ID.
@@ -96,7 +115,7 @@ func (s *AnomalyService) FindAnomalyList(ctx context.Context, find *api.AnomalyF
 func (s *AnomalyService) ArchiveAnomaly(ctx context.Context, archive *api.AnomalyArchive) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return FormatError(err)
+		return errors.WithStack(FormatError(err))
 	}
 	defer tx.Rollback()
 
@@ -106,7 +125,7 @@ func (s *AnomalyService) ArchiveAnomaly(ctx context.Context, archive *api.Anomal
 	}
 
 	if err := tx.Commit(); err != nil {
-		return FormatError(err)
+		return errors.WithStack(FormatError(err))
 	}
 
 	return nil
@@ -122,21 +141,23 @@ func createAnomaly(ctx context.Context, tx *Tx, upsert *api.AnomalyUpsert) (*api
 			instance_id,
 			database_id,
 			`+"`type`,"+`
+			severity,
 			payload
 		)
-		VALUES (?, ?, ?, ?, ?, ?)
-		RETURNING id, creator_id, created_ts, updater_id, updated_ts, instance_id, database_id, `+"`type`"+`, payload
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, instance_id, database_id, `+"`type`"+`, severity, payload
 	`,
 		upsert.CreatorId,
 		upsert.CreatorId,
 		upsert.InstanceId,
 		upsert.DatabaseId,
 		upsert.Type,
+		upsert.Severity,
 		upsert.Payload,
 	)
 
 	if err != nil {
-		return nil, FormatError(err)
+		return nil, errors.WithStack(FormatError(err))
 	}
 	defer row.Close()
 
@@ -152,41 +173,78 @@ func createAnomaly(ctx context.Context, tx *Tx, upsert *api.AnomalyUpsert) (*api
 		&anomaly.InstanceId,
 		&databaseId,
 		&anomaly.Type,
+		&anomaly.Severity,
 		&anomaly.Payload,
 	); err != nil {
-		return nil, FormatError(err)
+		return nil, errors.WithStack(FormatError(err))
 	}
 	if databaseId.Valid {
 		value := int(databaseId.Int32)
 		anomaly.DatabaseId = &value
 	}
 
-	return nil, err
+	return &anomaly, nil
 }
 
-func findAnomalyList(ctx context.Context, tx *Tx, find *api.AnomalyFind) (_ []*api.Anomaly, err error) {
-	// Build WHERE clause.
-	where, args := []string{"1 = 1"}, []interface{}{}
+// anomalyFindOrderByColumn allowlists the columns AnomalyFind.OrderBy may sort on, since OrderBy
+// is rendered verbatim into the query.
+var anomalyFindOrderByColumn = map[string]bool{
+	"id":         true,
+	"created_ts": true,
+	"updated_ts": true,
+}
+
+func buildAnomalyFindQuery(find *api.AnomalyFind) *queryBuilder {
+	b := newQueryBuilder()
 	if v := find.InstanceId; v != nil {
-		where, args = append(where, "instance_id = ?"), append(args, *v)
+		b.andEqual("instance_id", *v)
 		if find.InstanceOnly {
-			where = append(where, "database_id is NULL")
+			b.and("database_id IS NULL")
 		}
 	}
 	if find.InstanceId == nil || !find.InstanceOnly {
 		if v := find.DatabaseId; v != nil {
-			where, args = append(where, "database_id = ?"), append(args, *v)
+			b.andEqual("database_id", *v)
 		}
 	}
 	if v := find.RowStatus; v != nil {
-		where, args = append(where, "row_status = ?"), append(args, *v)
+		b.andEqual("row_status", *v)
 	}
 	if v := find.Type; v != nil {
-		where, args = append(where, "`type` = ?"), append(args, *v)
+		b.andEqual("`type`", *v)
+	}
+	if v := find.Severity; v != nil {
+		b.andEqual("severity", *v)
+	}
+	if v := find.CreatedAfter; v != nil {
+		b.and("created_ts >= ?", *v)
+	}
+	if v := find.CreatedBefore; v != nil {
+		b.and("created_ts < ?", *v)
+	}
+	return b
+}
+
+func findAnomalyList(ctx context.Context, tx *Tx, find *api.AnomalyFind) (_ []*api.Anomaly, total int, err error) {
+	b := buildAnomalyFindQuery(find)
+	where, args := b.whereClause()
+
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM anomaly `+where,
+		args...,
+	).Scan(&total); err != nil {
+		return nil, 0, errors.WithStack(FormatError(err))
 	}
 
+	orderBy := "id ASC"
+	if find.OrderBy != "" && anomalyFindOrderByColumn[find.OrderBy] {
+		orderBy = find.OrderBy
+	}
+	page := pageClause(orderBy, find.Limit, find.Offset)
+	pageArgs := pageArgs(find.Limit, find.Offset)
+
 	rows, err := tx.QueryContext(ctx, `
-		SELECT 
+		SELECT
 			id,
 			creator_id,
 			created_ts,
@@ -195,14 +253,15 @@ func findAnomalyList(ctx context.Context, tx *Tx, find *api.AnomalyFind) (_ []*a
 			instance_id,
 			database_id,
 			`+"`type`,"+`
+			severity,
 			payload
 		FROM anomaly
-		WHERE `+strings.Join(where, " AND ")+`
+		`+where+page+`
 		`,
-		args...,
+		append(append([]interface{}{}, args...), pageArgs...)...,
 	)
 	if err != nil {
-		return nil, FormatError(err)
+		return nil, 0, errors.WithStack(FormatError(err))
 	}
 	defer rows.Close()
 
@@ -220,9 +279,10 @@ func findAnomalyList(ctx context.Context, tx *Tx, find *api.AnomalyFind) (_ []*a
 			&anomaly.InstanceId,
 			&databaseId,
 			&anomaly.Type,
+			&anomaly.Severity,
 			&anomaly.Payload,
 		); err != nil {
-			return nil, FormatError(err)
+			return nil, 0, errors.WithStack(FormatError(err))
 		}
 		if databaseId.Valid {
 			value := int(databaseId.Int32)
@@ -232,10 +292,10 @@ func findAnomalyList(ctx context.Context, tx *Tx, find *api.AnomalyFind) (_ []*a
 		list = append(list, &anomaly)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, FormatError(err)
+		return nil, 0, errors.WithStack(FormatError(err))
 	}
 
-	return list, nil
+	return list, total, nil
 }
 
 type anomalyPatch struct {
@@ -245,13 +305,15 @@ type anomalyPatch struct {
 	UpdaterId int
 
 	// Domain specific fields
-	Payload string
+	Severity api.AnomalySeverity
+	Payload  string
 }
 
 // patchAnomaly patches an anomaly
 func patchAnomaly(ctx context.Context, tx *Tx, patch *anomalyPatch) (*api.Anomaly, error) {
 	// Build UPDATE clause.
 	set, args := []string{"updater_id = ?"}, []interface{}{patch.UpdaterId}
+	set, args = append(set, "severity = ?"), append(args, patch.Severity)
 	set, args = append(set, "payload = ?"), append(args, patch.Payload)
 	args = append(args, patch.ID)
 
@@ -260,17 +322,12 @@ func patchAnomaly(ctx context.Context, tx *Tx, patch *anomalyPatch) (*api.Anomal
 		UPDATE anomaly
 		SET `+strings.Join(set, ", ")+`
 		WHERE id = ?
-		RETURNING id, creator_id, created_ts, updater_id, updated_ts, instance_id, database_id, `+"`type`"+`, payload
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, instance_id, database_id, `+"`type`"+`, severity, payload
 	`,
 		args...,
 	)
 	if err != nil {
-		return nil, FormatError(err)
-	}
-	defer row.Close()
-
-	if err != nil {
-		return nil, FormatError(err)
+		return nil, errors.WithStack(FormatError(err))
 	}
 	defer row.Close()
 
@@ -286,9 +343,10 @@ func patchAnomaly(ctx context.Context, tx *Tx, patch *anomalyPatch) (*api.Anomal
 		&anomaly.InstanceId,
 		&anomaly.DatabaseId,
 		&anomaly.Type,
+		&anomaly.Severity,
 		&anomaly.Payload,
 	); err != nil {
-		return nil, FormatError(err)
+		return nil, errors.WithStack(FormatError(err))
 	}
 	if databaseId.Valid {
 		value := int(databaseId.Int32)
@@ -298,13 +356,79 @@ func patchAnomaly(ctx context.Context, tx *Tx, patch *anomalyPatch) (*api.Anomal
 	return &anomaly, err
 }
 
+// ExpireStaleAnomalyList archives every active anomaly for the instance/database in expire
+// whose type is not in expire.SeenTypes, i.e. the anomaly is no longer detected by the latest
+// scan, and returns the types that were archived. This is used by the anomaly scheduler so a
+// resolved anomaly (e.g. backup restored, connection recovered) does not linger as active forever,
+// and so it can publish an Expired event for each one instead of only ever publishing on upsert.
+func (s *AnomalyService) ExpireStaleAnomalyList(ctx context.Context, expire api.AnomalyExpire) ([]api.AnomalyType, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.WithStack(FormatError(err))
+	}
+	defer tx.Rollback()
+
+	archived, err := expireStaleAnomalyList(ctx, tx, expire)
+	if err != nil {
+		return nil, errors.WithStack(FormatError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.WithStack(FormatError(err))
+	}
+
+	return archived, nil
+}
+
+// expireStaleAnomalyList archives every active anomaly matching expire whose type is not
+// in expire.SeenTypes, and returns the distinct types that were archived.
+func expireStaleAnomalyList(ctx context.Context, tx *Tx, expire api.AnomalyExpire) ([]api.AnomalyType, error) {
+	where, args := []string{"row_status = ?"}, []interface{}{api.Normal}
+	if expire.DatabaseId != nil {
+		where, args = append(where, "database_id = ?"), append(args, *expire.DatabaseId)
+	} else {
+		where, args = append(where, "instance_id = ?", "database_id IS NULL"), append(args, expire.InstanceId)
+	}
+	if len(expire.SeenTypes) > 0 {
+		placeholder := make([]string, len(expire.SeenTypes))
+		for i, seenType := range expire.SeenTypes {
+			placeholder[i] = "?"
+			args = append(args, seenType)
+		}
+		where = append(where, "`type` NOT IN ("+strings.Join(placeholder, ", ")+")")
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`UPDATE anomaly SET row_status = ? WHERE `+strings.Join(where, " AND ")+` RETURNING `+"`type`",
+		append([]interface{}{api.Archived}, args...)...,
+	)
+	if err != nil {
+		return nil, errors.WithStack(FormatError(err))
+	}
+	defer rows.Close()
+
+	var archived []api.AnomalyType
+	for rows.Next() {
+		var anomalyType api.AnomalyType
+		if err := rows.Scan(&anomalyType); err != nil {
+			return nil, errors.WithStack(FormatError(err))
+		}
+		archived = append(archived, anomalyType)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WithStack(FormatError(err))
+	}
+
+	return archived, nil
+}
+
 // archiveAnomaly archives an anomaly by ID.
 func archiveAnomaly(ctx context.Context, tx *Tx, archive *api.AnomalyArchive) error {
 	if archive.InstanceId == nil && archive.DatabaseId == nil {
-		return &common.Error{Code: common.Internal, Err: fmt.Errorf("failed to close anomaly, should specify either instanceId or databaseId")}
+		return &common.Error{Code: common.Internal, Err: errors.Errorf("failed to close anomaly, should specify either instanceId or databaseId")}
 	}
 	if archive.InstanceId != nil && archive.DatabaseId != nil {
-		return &common.Error{Code: common.Internal, Err: fmt.Errorf("failed to close anomaly, should specify either instanceId or databaseId, but not both")}
+		return &common.Error{Code: common.Internal, Err: errors.Errorf("failed to close anomaly, should specify either instanceId or databaseId, but not both")}
 	}
 	// Remove row from database.
 	if archive.InstanceId != nil {
@@ -315,12 +439,12 @@ func archiveAnomaly(ctx context.Context, tx *Tx, archive *api.AnomalyArchive) er
 			archive.Type,
 		)
 		if err != nil {
-			return FormatError(err)
+			return errors.WithStack(FormatError(err))
 		}
 
 		rows, _ := result.RowsAffected()
 		if rows == 0 {
-			return &common.Error{Code: common.NotFound, Err: fmt.Errorf("anomaly not found instance: %d type: %s", *archive.InstanceId, archive.Type)}
+			return &common.Error{Code: common.NotFound, Err: errors.Errorf("anomaly not found instance: %d type: %s", *archive.InstanceId, archive.Type)}
 		}
 	} else if archive.DatabaseId != nil {
 		result, err := tx.ExecContext(ctx,
@@ -330,12 +454,12 @@ func archiveAnomaly(ctx context.Context, tx *Tx, archive *api.AnomalyArchive) er
 			archive.Type,
 		)
 		if err != nil {
-			return FormatError(err)
+			return errors.WithStack(FormatError(err))
 		}
 
 		rows, _ := result.RowsAffected()
 		if rows == 0 {
-			return &common.Error{Code: common.NotFound, Err: fmt.Errorf("anomaly not found database: %d type: %s", *archive.DatabaseId, archive.Type)}
+			return &common.Error{Code: common.NotFound, Err: errors.Errorf("anomaly not found database: %d type: %s", *archive.DatabaseId, archive.Type)}
 		}
 	}
 
@@ -0,0 +1,98 @@
+package store
+
+import "strings"
+
+// queryBuilder accumulates parameterized WHERE fragments for a store finder, in the spirit of
+// xorm/builder, so finders do not grow into ad-hoc string concatenation (e.g. "1 = 1" plus
+// manual `+ " AND "` joins) as the number of optional filters increases.
+//
+// queryBuilder is intentionally minimal: it only covers what the store package's finders need
+// (AND-ed equality/range/IN fragments plus ORDER BY/LIMIT/OFFSET), not a general SQL DSL.
+type queryBuilder struct {
+	where []string
+	args  []interface{}
+}
+
+// newQueryBuilder returns an empty queryBuilder.
+func newQueryBuilder() *queryBuilder {
+	return &queryBuilder{}
+}
+
+// andEqual adds `column = ?` to the WHERE clause and binds value, if value is non-nil.
+func (b *queryBuilder) andEqual(column string, value interface{}) *queryBuilder {
+	if value == nil {
+		return b
+	}
+	b.where, b.args = append(b.where, column+" = ?"), append(b.args, value)
+	return b
+}
+
+// and adds a raw condition with its bound args to the WHERE clause, unconditionally.
+func (b *queryBuilder) and(condition string, args ...interface{}) *queryBuilder {
+	b.where, b.args = append(b.where, condition), append(b.args, args...)
+	return b
+}
+
+// andIn adds `column IN (?, ?, ...)` to the WHERE clause, if values is non-empty.
+func (b *queryBuilder) andIn(column string, values []interface{}) *queryBuilder {
+	if len(values) == 0 {
+		return b
+	}
+	placeholder := make([]string, len(values))
+	for i := range values {
+		placeholder[i] = "?"
+	}
+	b.where, b.args = append(b.where, column+" IN ("+strings.Join(placeholder, ", ")+")"), append(b.args, values...)
+	return b
+}
+
+// whereClause renders the accumulated conditions as a "WHERE ..." clause (defaulting to
+// "WHERE 1 = 1" when no condition was added) and returns its bound args in the same order.
+func (b *queryBuilder) whereClause() (string, []interface{}) {
+	if len(b.where) == 0 {
+		return "WHERE 1 = 1", nil
+	}
+	return "WHERE " + strings.Join(b.where, " AND "), b.args
+}
+
+// noLimitSentinel stands in for "no limit" when a caller sets Offset without Limit (e.g. "skip N,
+// take the rest"). MySQL and SQLite both require a LIMIT clause whenever OFFSET is present, so
+// pageClause cannot just omit it; this is comfortably larger than any result set the store will
+// ever page through.
+const noLimitSentinel = int(^uint(0) >> 1) // math.MaxInt, without importing math for one constant
+
+// pageClause renders an "ORDER BY ... LIMIT ... OFFSET ..." clause. orderBy is used verbatim
+// (callers must validate it against an allowlist of columns). limit/offset are only rendered
+// when positive so an unset page request returns every row, consistent with the pre-pagination
+// behavior of the store's finders — except LIMIT is also rendered, with noLimitSentinel bound to
+// it, whenever offset is positive on its own, since OFFSET without a preceding LIMIT is a SQL
+// syntax error.
+func pageClause(orderBy string, limit, offset int) string {
+	var sb strings.Builder
+	if orderBy != "" {
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(orderBy)
+	}
+	if limit > 0 || offset > 0 {
+		sb.WriteString(" LIMIT ?")
+	}
+	if offset > 0 {
+		sb.WriteString(" OFFSET ?")
+	}
+	return sb.String()
+}
+
+// pageArgs returns the args to append after the main WHERE args for the clause rendered by
+// pageClause with the same limit/offset.
+func pageArgs(limit, offset int) []interface{} {
+	var args []interface{}
+	if limit > 0 {
+		args = append(args, limit)
+	} else if offset > 0 {
+		args = append(args, noLimitSentinel)
+	}
+	if offset > 0 {
+		args = append(args, offset)
+	}
+	return args
+}
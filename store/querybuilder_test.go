@@ -0,0 +1,52 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageClauseAndArgs(t *testing.T) {
+	tests := []struct {
+		orderBy    string
+		limit      int
+		offset     int
+		wantClause string
+		wantArgs   []interface{}
+	}{
+		{
+			orderBy:    "",
+			limit:      0,
+			offset:     0,
+			wantClause: "",
+			wantArgs:   nil,
+		},
+		{
+			orderBy:    "id ASC",
+			limit:      10,
+			offset:     0,
+			wantClause: " ORDER BY id ASC LIMIT ?",
+			wantArgs:   []interface{}{10},
+		},
+		{
+			orderBy:    "id ASC",
+			limit:      0,
+			offset:     20,
+			wantClause: " ORDER BY id ASC LIMIT ? OFFSET ?",
+			wantArgs:   []interface{}{noLimitSentinel, 20},
+		},
+		{
+			orderBy:    "id ASC",
+			limit:      10,
+			offset:     20,
+			wantClause: " ORDER BY id ASC LIMIT ? OFFSET ?",
+			wantArgs:   []interface{}{10, 20},
+		},
+	}
+
+	a := require.New(t)
+	for _, tc := range tests {
+		a.Equal(tc.wantClause, pageClause(tc.orderBy, tc.limit, tc.offset))
+		a.Equal(tc.wantArgs, pageArgs(tc.limit, tc.offset))
+	}
+}